@@ -12,12 +12,16 @@ type Template struct {
 	RequiresNumeric    bool
 	RequiresComparable bool
 	RequiresOrdered    bool
+	Imports            []string
 }
 
 func getTemplate(name string) (result *template.Template, err error) {
 	if isProjectionMethod(name) {
 		return getProjectionTemplate(name)
 	}
+	if isQueryMethod(name) {
+		return getQueryTemplate(name)
+	}
 	return getStandardTemplate(name)
 }
 
@@ -71,10 +75,59 @@ func getProjectionMethodKeys() (result []string) {
 	return
 }
 
+func getQueryTemplate(name string) (result *template.Template, err error) {
+	t, found := QueryMethods[name]
+	if found {
+		result = template.Must(template.New(name).Parse(t.Text))
+	} else {
+		err = fmt.Errorf("%s is not a known query method", name)
+	}
+	return
+}
+
+func isQueryMethod(s string) bool {
+	_, ok := QueryMethods[s]
+	return ok
+}
+
+func getQueryMethodKeys() (result []string) {
+	for k := range QueryMethods {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return
+}
+
 func getSortSupportTemplate() *template.Template {
 	return template.Must(template.New("sortSupport").Parse(sortSupport))
 }
 
+// getImports returns the set of extra package imports contributed by the
+// given method names, beyond whatever the header already imports on its
+// own. Standard and projection methods are both consulted, and duplicate
+// imports (e.g. two methods both needing "sync") are collapsed.
+func getImports(methods []string) (result []string) {
+	seen := make(map[string]bool)
+	add := func(imports []string) {
+		for _, imp := range imports {
+			if !seen[imp] {
+				seen[imp] = true
+				result = append(result, imp)
+			}
+		}
+	}
+	for _, m := range methods {
+		if t, found := standardTemplates[m]; found {
+			add(t.Imports)
+		}
+		if t, found := ProjectionMethods[m]; found {
+			add(t.Imports)
+		}
+	}
+	sort.Strings(result)
+	return
+}
+
 const header = `// This file was auto-generated using github.com/clipperhouse/gen
 // Modifying this file is not recommended as it will likely be overwritten in the future
 
@@ -257,6 +310,40 @@ func (rcv {{.Plural}}) Single(fn func({{.Pointer}}{{.Name}}) bool) (result {{.Po
 	}
 	return
 }
+`},
+
+	"BinarySearchBy": &Template{
+		Text: `
+// BinarySearchBy searches for target within rcv, which must already be sorted by the passed func defining ‘less’ (see SortBy). It returns the position where target was found, or where it would be inserted, and whether it was found. See: http://clipperhouse.github.io/gen/#BinarySearchBy
+func (rcv {{.Plural}}) BinarySearchBy(less func({{.Pointer}}{{.Name}}, {{.Pointer}}{{.Name}}) bool, target {{.Pointer}}{{.Name}}) (int, bool) {
+	i, j := 0, len(rcv)
+	for i < j {
+		h := i + (j-i)/2
+		if less(rcv[h], target) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i, i < len(rcv) && !less(target, rcv[i]) && !less(rcv[i], target)
+}
+`},
+
+	"SearchBy": &Template{
+		Text: `
+// SearchBy returns the smallest index for which fn returns true, assuming fn is false for some prefix of rcv and true for the remainder. Returns len(rcv) if fn is never true. See: http://clipperhouse.github.io/gen/#SearchBy
+func (rcv {{.Plural}}) SearchBy(fn func({{.Pointer}}{{.Name}}) bool) int {
+	i, j := 0, len(rcv)
+	for i < j {
+		h := i + (j-i)/2
+		if !fn(rcv[h]) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
 `},
 
 	"Where": &Template{
@@ -270,6 +357,24 @@ func (rcv {{.Plural}}) Where(fn func({{.Pointer}}{{.Name}}) bool) (result {{.Plu
 	}
 	return result
 }
+`},
+
+	"PartitionBy": &Template{
+		// PartitionBy takes a predicate, not a key func, so unlike KeyBy/CountBy/IndexBy
+		// it has no key type to compare and lives in standardTemplates rather than
+		// ProjectionMethods; RequiresComparable does not apply here.
+		Text: `
+// PartitionBy splits {{.Plural}} in one pass into the elements for which fn returns true, and the elements for which it returns false. See: http://clipperhouse.github.io/gen/#PartitionBy
+func (rcv {{.Plural}}) PartitionBy(fn func({{.Pointer}}{{.Name}}) bool) (truthy {{.Plural}}, falsy {{.Plural}}) {
+	for _, v := range rcv {
+		if fn(v) {
+			truthy = append(truthy, v)
+		} else {
+			falsy = append(falsy, v)
+		}
+	}
+	return
+}
 `},
 
 	"SortBy": &Template{
@@ -327,6 +432,200 @@ func (rcv {{.Plural}}) IsSortedByDesc(less func({{.Pointer}}{{.Name}}, {{.Pointe
 	return rcv.IsSortedBy(greaterOrEqual)
 }
 `},
+
+	"SortStableBy": &Template{
+		Text: `
+// SortStableBy returns a new, stably-ordered {{.Plural}} slice, determined by a func defining ‘less’. Equal elements preserve their original relative order, unlike SortBy. See: http://clipperhouse.github.io/gen/#SortStableBy
+func (rcv {{.Plural}}) SortStableBy(less func({{.Pointer}}{{.Name}}, {{.Pointer}}{{.Name}}) bool) {{.Plural}} {
+	result := make({{.Plural}}, len(rcv))
+	copy(result, rcv)
+	stableSort{{.Plural}}(result, less, 0, len(result))
+	return result
+}
+`},
+
+	"IsSortedStableBy": &Template{
+		Text: `
+// IsSortedStableBy reports whether an instance of {{.Plural}} is sorted, using the passed func to define ‘less’. Stability only affects SortStableBy's output, not this check, so this is equivalent to IsSortedBy. See: http://clipperhouse.github.io/gen/#SortStableBy
+func (rcv {{.Plural}}) IsSortedStableBy(less func({{.Pointer}}{{.Name}}, {{.Pointer}}{{.Name}}) bool) bool {
+	return rcv.IsSortedBy(less)
+}
+`},
+
+	"ParallelEach": &Template{
+		Text: `
+// ParallelEach iterates over {{.Plural}} and executes the passed func against each element, fanning the work out across the given number of workers. See: http://clipperhouse.github.io/gen/#ParallelEach
+func (rcv {{.Plural}}) ParallelEach(fn func({{.Pointer}}{{.Name}}), workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	work := make(chan {{.Pointer}}{{.Name}})
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for v := range work {
+				fn(v)
+			}
+		}()
+	}
+	for _, v := range rcv {
+		work <- v
+	}
+	close(work)
+	wg.Wait()
+}
+`,
+		Imports: []string{"sync"},
+	},
+
+	"ParallelWhere": &Template{
+		Text: `
+// ParallelWhere returns a new {{.Plural}} slice whose elements return true for the passed func, evaluating fn across the given number of workers. The relative order of rcv is preserved in the result. See: http://clipperhouse.github.io/gen/#ParallelWhere
+func (rcv {{.Plural}}) ParallelWhere(fn func({{.Pointer}}{{.Name}}) bool, workers int) (result {{.Plural}}) {
+	if workers < 1 {
+		workers = 1
+	}
+	keep := make([]bool, len(rcv))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				keep[i] = fn(rcv[i])
+			}
+		}()
+	}
+	for i := range rcv {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	for i, v := range rcv {
+		if keep[i] {
+			result = append(result, v)
+		}
+	}
+	return
+}
+`,
+		Imports: []string{"sync"},
+	},
+
+	"Intersect": &Template{
+		Text: `
+// Intersect returns a new {{.Plural}} slice containing only the elements found in both rcv and other. See: http://clipperhouse.github.io/gen/#Intersect
+func (rcv {{.Plural}}) Intersect(other {{.Plural}}) (result {{.Plural}}) {
+	in := make(map[{{.Pointer}}{{.Name}}]bool)
+	for _, v := range other {
+		in[v] = true
+	}
+	seen := make(map[{{.Pointer}}{{.Name}}]bool)
+	for _, v := range rcv {
+		if in[v] && !seen[v] {
+			result = append(result, v)
+			seen[v] = true
+		}
+	}
+	return
+}
+`,
+		RequiresComparable: true,
+	},
+
+	"Union": &Template{
+		Text: `
+// Union returns a new {{.Plural}} slice containing the unique elements of rcv, followed by the unique elements of other that are not already present. See: http://clipperhouse.github.io/gen/#Union
+func (rcv {{.Plural}}) Union(other {{.Plural}}) (result {{.Plural}}) {
+	seen := make(map[{{.Pointer}}{{.Name}}]bool)
+	for _, v := range rcv {
+		if !seen[v] {
+			result = append(result, v)
+			seen[v] = true
+		}
+	}
+	for _, v := range other {
+		if !seen[v] {
+			result = append(result, v)
+			seen[v] = true
+		}
+	}
+	return
+}
+`,
+		RequiresComparable: true,
+	},
+
+	"Difference": &Template{
+		Text: `
+// Difference returns the elements found only in rcv, and the elements found only in other. See: http://clipperhouse.github.io/gen/#Difference
+func (rcv {{.Plural}}) Difference(other {{.Plural}}) (left {{.Plural}}, right {{.Plural}}) {
+	inOther := make(map[{{.Pointer}}{{.Name}}]bool)
+	for _, v := range other {
+		inOther[v] = true
+	}
+	inRcv := make(map[{{.Pointer}}{{.Name}}]bool)
+	for _, v := range rcv {
+		inRcv[v] = true
+		if !inOther[v] {
+			left = append(left, v)
+		}
+	}
+	for _, v := range other {
+		if !inRcv[v] {
+			right = append(right, v)
+		}
+	}
+	return
+}
+`,
+		RequiresComparable: true,
+	},
+
+	"Chunk": &Template{
+		Text: `
+// Chunk splits {{.Plural}} into chunks of the given size. The last chunk may have fewer than size elements. Returns a single chunk containing all of rcv if size is not positive. See: http://clipperhouse.github.io/gen/#Chunk
+func (rcv {{.Plural}}) Chunk(size int) (result []{{.Plural}}) {
+	if size <= 0 {
+		return append(result, rcv)
+	}
+	for size < len(rcv) {
+		rcv, result = rcv[size:], append(result, rcv[0:size:size])
+	}
+	return append(result, rcv)
+}
+`},
+
+	"Reverse": &Template{
+		Text: `
+// Reverse returns a new {{.Plural}} slice with the elements in reverse order. See: http://clipperhouse.github.io/gen/#Reverse
+func (rcv {{.Plural}}) Reverse() {{.Plural}} {
+	result := make({{.Plural}}, len(rcv))
+	for i, v := range rcv {
+		result[len(rcv)-1-i] = v
+	}
+	return result
+}
+`},
+
+	"Shuffle": &Template{
+		Text: `
+// Shuffle returns a new {{.Plural}} slice with the elements in random order, using a Fisher–Yates shuffle. See: http://clipperhouse.github.io/gen/#Shuffle
+func (rcv {{.Plural}}) Shuffle() {{.Plural}} {
+	result := make({{.Plural}}, len(rcv))
+	copy(result, rcv)
+	for i := len(result) - 1; i > 0; i-- {
+		j := rand.Intn(i + 1)
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+`,
+		Imports: []string{"math/rand"},
+	},
 }
 
 const sortSupport = `
@@ -502,6 +801,127 @@ func quickSort{{.Plural}}(rcv {{.Plural}}, less func({{.Pointer}}{{.Name}}, {{.P
 		insertionSort{{.Plural}}(rcv, less, a, b)
 	}
 }
+
+// Stable sort, following the block-merge approach used by sort.Stable.
+
+func reverse{{.Plural}}(rcv {{.Plural}}, a, b int) {
+	for i, j := a, b-1; i < j; i, j = i+1, j-1 {
+		swap{{.Plural}}(rcv, i, j)
+	}
+}
+
+// rotate swaps the two blocks rcv[a,m) and rcv[m,b) in place, via three reversals.
+func rotate{{.Plural}}(rcv {{.Plural}}, a, m, b int) {
+	reverse{{.Plural}}(rcv, a, m)
+	reverse{{.Plural}}(rcv, m, b)
+	reverse{{.Plural}}(rcv, a, b)
+}
+
+// symMerge merges the two sorted ranges rcv[a,m) and rcv[m,b) in place,
+// using the Symmetric Merge algorithm (Kim and Kutzner, "Ratio Based Stable
+// In-Place Merging").
+func symMerge{{.Plural}}(rcv {{.Plural}}, less func({{.Pointer}}{{.Name}}, {{.Pointer}}{{.Name}}) bool, a, m, b int) {
+	// Avoid unnecessary recursion by direct insertion of rcv[a] into
+	// rcv[m:b] if rcv[a:m] only contains one element.
+	if m-a == 1 {
+		i := m
+		j := b
+		for i < j {
+			h := i + (j-i)/2
+			if less(rcv[h], rcv[a]) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := a; k < i-1; k++ {
+			swap{{.Plural}}(rcv, k, k+1)
+		}
+		return
+	}
+
+	// Avoid unnecessary recursion by direct insertion of rcv[m] into
+	// rcv[a:m] if rcv[m:b] only contains one element.
+	if b-m == 1 {
+		i := a
+		j := m
+		for i < j {
+			h := i + (j-i)/2
+			if !less(rcv[m], rcv[h]) {
+				i = h + 1
+			} else {
+				j = h
+			}
+		}
+		for k := m; k > i; k-- {
+			swap{{.Plural}}(rcv, k, k-1)
+		}
+		return
+	}
+
+	mid := a + (b-a)/2
+	n := mid + m
+	var start, r int
+	if m > mid {
+		start = n - b
+		r = mid
+	} else {
+		start = a
+		r = m
+	}
+	p := n - 1
+	for start < r {
+		c := start + (r-start)/2
+		if !less(rcv[p-c], rcv[c]) {
+			start = c + 1
+		} else {
+			r = c
+		}
+	}
+
+	end := n - start
+	if start < m && m < end {
+		rotate{{.Plural}}(rcv, start, m, end)
+	}
+	if a < start && start < mid {
+		symMerge{{.Plural}}(rcv, less, a, start, mid)
+	}
+	if mid < end && end < b {
+		symMerge{{.Plural}}(rcv, less, mid, end, b)
+	}
+}
+
+// stableSort insertion-sorts blocks of 20 elements, then repeatedly
+// symMerges adjacent sorted blocks, doubling the block size each pass.
+func stableSort{{.Plural}}(rcv {{.Plural}}, less func({{.Pointer}}{{.Name}}, {{.Pointer}}{{.Name}}) bool, a, b int) {
+	const insertionBlock = 20
+
+	blockSize := insertionBlock
+	for start := a; start < b; start += blockSize {
+		end := start + blockSize
+		if end > b {
+			end = b
+		}
+		insertionSort{{.Plural}}(rcv, less, start, end)
+	}
+
+	for blockSize < b-a {
+		start := a
+		for start < b {
+			mid := start + blockSize
+			end := mid + blockSize
+			if end > b {
+				end = b
+			}
+			if mid >= end {
+				break
+			}
+			symMerge{{.Plural}}(rcv, less, start, mid, end)
+			start = end
+		}
+		blockSize *= 2
+	}
+}
 `
 
 var ProjectionMethods = map[string]*Template{
@@ -550,6 +970,48 @@ func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Pare
 		RequiresComparable: true,
 	},
 
+	"KeyBy": &Template{
+		Text: `
+// {{.MethodName}} returns a map keyed by {{.Type}}, where each value is the last element of {{.Parent.Plural}} to produce that key. See: http://clipperhouse.github.io/gen/#KeyBy
+func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Parent.Name}}) {{.Type}}) map[{{.Type}}]{{.Parent.Pointer}}{{.Parent.Name}} {
+	result := make(map[{{.Type}}]{{.Parent.Pointer}}{{.Parent.Name}})
+	for _, v := range rcv {
+		result[fn(v)] = v
+	}
+	return result
+}
+`,
+		RequiresComparable: true,
+	},
+
+	"CountBy": &Template{
+		Text: `
+// {{.MethodName}} returns a map keyed by {{.Type}}, counting the number of {{.Parent.Plural}} elements that produce each key. See: http://clipperhouse.github.io/gen/#CountBy
+func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Parent.Name}}) {{.Type}}) map[{{.Type}}]int {
+	result := make(map[{{.Type}}]int)
+	for _, v := range rcv {
+		result[fn(v)]++
+	}
+	return result
+}
+`,
+		RequiresComparable: true,
+	},
+
+	"IndexBy": &Template{
+		Text: `
+// {{.MethodName}} returns a map keyed by {{.Type}}, where each value is the index of the last element of {{.Parent.Plural}} to produce that key. See: http://clipperhouse.github.io/gen/#IndexBy
+func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Parent.Name}}) {{.Type}}) map[{{.Type}}]int {
+	result := make(map[{{.Type}}]int)
+	for i, v := range rcv {
+		result[fn(v)] = i
+	}
+	return result
+}
+`,
+		RequiresComparable: true,
+	},
+
 	"Max": &Template{
 		Text: `
 // {{.MethodName}} selects the largest value of {{.Type}} in {{.Parent.Plural}}. Returns error on {{.Parent.Plural}} with no elements. See: http://clipperhouse.github.io/gen/#MaxCustom
@@ -610,6 +1072,92 @@ func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Pare
 `,
 	},
 
+	"ParallelSelect": &Template{
+		Text: `
+// {{.MethodName}} returns a slice of {{.Type}} in {{.Parent.Plural}}, projected by passed func across the given number of workers. The order of results matches {{.Parent.Plural}}. See: http://clipperhouse.github.io/gen/#ParallelSelect
+func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Parent.Name}}) {{.Type}}, workers int) (result []{{.Type}}) {
+	if workers < 1 {
+		workers = 1
+	}
+	result = make([]{{.Type}}, len(rcv))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result[i] = fn(rcv[i])
+			}
+		}()
+	}
+	for i := range rcv {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return
+}
+`,
+		Imports: []string{"sync"},
+	},
+
+	"ParallelAggregate": &Template{
+		Text: `
+// {{.MethodName}} iterates over {{.Parent.Plural}} across the given number of workers, aggregating each worker's share with fn, then reduces the partial results with combine. Each worker's share is a contiguous run of elements folded from the zero value of {{.Type}}, and the partial results are combined in source order, so combine must be associative. See: http://clipperhouse.github.io/gen/#ParallelAggregate
+func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Type}}, {{.Parent.Pointer}}{{.Parent.Name}}) {{.Type}}, combine func({{.Type}}, {{.Type}}) {{.Type}}, workers int) (result {{.Type}}) {
+	l := len(rcv)
+	if l == 0 {
+		return
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > l {
+		workers = l
+	}
+	chunk := (l + workers - 1) / workers
+	partials := make([]{{.Type}}, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			a := w * chunk
+			b := a + chunk
+			if b > l {
+				b = l
+			}
+			var partial {{.Type}}
+			for _, v := range rcv[a:b] {
+				partial = fn(partial, v)
+			}
+			partials[w] = partial
+		}(w)
+	}
+	wg.Wait()
+	result = partials[0]
+	for _, p := range partials[1:] {
+		result = combine(result, p)
+	}
+	return
+}
+`,
+		Imports: []string{"sync"},
+	},
+
+	"Flatten": &Template{
+		Text: `
+// {{.MethodName}} concatenates the {{.Type}} slices held by {{.Parent.Plural}} into a single slice. See: http://clipperhouse.github.io/gen/#Flatten
+func (rcv {{.Parent.Plural}}) {{.MethodName}}() (result []{{.Type}}) {
+	for _, v := range rcv {
+		result = append(result, v...)
+	}
+	return
+}
+`,
+	},
+
 	"Sum": &Template{
 		Text: `
 // {{.MethodName}} sums {{.Type}} over elements in {{.Parent.Plural}}. See: http://clipperhouse.github.io/gen/#Sum
@@ -623,3 +1171,162 @@ func (rcv {{.Parent.Plural}}) {{.MethodName}}(fn func({{.Parent.Pointer}}{{.Pare
 		RequiresNumeric: true,
 	},
 }
+
+// QueryMethods holds the lazy pipeline family. Unlike standardTemplates and
+// ProjectionMethods, "AsQuery" emits an entire shared type ({{.Plural}}Query)
+// plus its methods in one block, analogous to how sortSupport is generated
+// once per type rather than once per method.
+var QueryMethods = map[string]*Template{
+	"AsQuery": &Template{
+		Text: `
+// {{.Plural}}Query is a lazy, chainable pipeline over {{.Plural}}. Each stage is a deferred stage factory; nothing runs until a terminal method (ToSlice, First, Count, Any) drives a single pass over the source. A query value may be reused and branched freely: every run constructs fresh per-pass state, and every chaining method copies its stage list, so derived queries never share mutable state or a backing array.
+type {{.Plural}}Query struct {
+	source {{.Plural}}
+	stages []func() func({{.Pointer}}{{.Name}}) (value {{.Pointer}}{{.Name}}, keep bool, stop bool)
+}
+
+// AsQuery enters a lazy pipeline over {{.Plural}}. See: http://clipperhouse.github.io/gen/#AsQuery
+func (rcv {{.Plural}}) AsQuery() {{.Plural}}Query {
+	return {{.Plural}}Query{source: rcv}
+}
+
+// withStage returns a copy of q with stage appended, without mutating or sharing q's backing array, so branching from a common query prefix is safe.
+func (q {{.Plural}}Query) withStage(stage func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool)) {{.Plural}}Query {
+	q.stages = append(q.stages[:len(q.stages):len(q.stages)], stage)
+	return q
+}
+
+// Where adds a stage that keeps only elements for which fn returns true.
+func (q {{.Plural}}Query) Where(fn func({{.Pointer}}{{.Name}}) bool) {{.Plural}}Query {
+	return q.withStage(func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+		return func(v {{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+			return v, fn(v), false
+		}
+	})
+}
+
+// Select adds a stage that transforms each element using fn.
+func (q {{.Plural}}Query) Select(fn func({{.Pointer}}{{.Name}}) {{.Pointer}}{{.Name}}) {{.Plural}}Query {
+	return q.withStage(func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+		return func(v {{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+			return fn(v), true, false
+		}
+	})
+}
+
+// Take adds a stage that keeps at most the first n elements, short-circuiting the pass once satisfied.
+func (q {{.Plural}}Query) Take(n int) {{.Plural}}Query {
+	return q.withStage(func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+		taken := 0
+		return func(v {{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+			if taken >= n {
+				return v, false, true
+			}
+			taken++
+			return v, true, taken >= n
+		}
+	})
+}
+
+// Skip adds a stage that discards the first n elements.
+func (q {{.Plural}}Query) Skip(n int) {{.Plural}}Query {
+	return q.withStage(func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+		skipped := 0
+		return func(v {{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+			if skipped < n {
+				skipped++
+				return v, false, false
+			}
+			return v, true, false
+		}
+	})
+}
+
+// Distinct adds a stage that keeps only the first occurrence of each value.
+func (q {{.Plural}}Query) Distinct() {{.Plural}}Query {
+	return q.withStage(func() func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+		seen := make(map[{{.Pointer}}{{.Name}}]bool)
+		return func(v {{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool) {
+			if seen[v] {
+				return v, false, false
+			}
+			seen[v] = true
+			return v, true, false
+		}
+	})
+}
+
+// run drives a single pass over the source, instantiating fresh per-pass state for every stage and applying them to each element in turn until a stage signals stop.
+func (q {{.Plural}}Query) run(visit func({{.Pointer}}{{.Name}}) bool) {
+	active := make([]func({{.Pointer}}{{.Name}}) ({{.Pointer}}{{.Name}}, bool, bool), len(q.stages))
+	for i, stage := range q.stages {
+		active[i] = stage()
+	}
+	for _, v := range q.source {
+		cur := v
+		keep := true
+		stop := false
+		for _, stage := range active {
+			var k, s bool
+			cur, k, s = stage(cur)
+			if s {
+				stop = true
+			}
+			if !k {
+				keep = false
+				break
+			}
+		}
+		if keep && !visit(cur) {
+			return
+		}
+		if stop {
+			return
+		}
+	}
+}
+
+// ToSlice materializes the pipeline into a new {{.Plural}} slice.
+func (q {{.Plural}}Query) ToSlice() (result {{.Plural}}) {
+	q.run(func(v {{.Pointer}}{{.Name}}) bool {
+		result = append(result, v)
+		return true
+	})
+	return
+}
+
+// First returns the first element to pass the pipeline. Returns error if none do.
+func (q {{.Plural}}Query) First() (result {{.Pointer}}{{.Name}}, err error) {
+	found := false
+	q.run(func(v {{.Pointer}}{{.Name}}) bool {
+		result = v
+		found = true
+		return false
+	})
+	if !found {
+		err = errors.New("no {{.Plural}} elements passed the query")
+	}
+	return
+}
+
+// Count returns the number of elements that pass the pipeline.
+func (q {{.Plural}}Query) Count() (result int) {
+	q.run(func(v {{.Pointer}}{{.Name}}) bool {
+		result++
+		return true
+	})
+	return
+}
+
+// Any reports whether at least one element passes the pipeline.
+func (q {{.Plural}}Query) Any() (result bool) {
+	q.run(func(v {{.Pointer}}{{.Name}}) bool {
+		result = true
+		return false
+	})
+	return
+}
+`,
+		RequiresComparable: true,
+	},
+}